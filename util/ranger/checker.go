@@ -110,10 +110,12 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 		}
 		return c.checkColumn(scalar.GetArgs()[0])
 	case ast.UnaryNot:
-		// TODO: support "not like" convert to access conditions.
 		if s, ok := scalar.GetArgs()[0].(*expression.ScalarFunction); ok {
 			if s.FuncName.L == ast.Like {
-				return false
+				if !c.isFullLength {
+					c.shouldReserve = true
+				}
+				return c.checkNotLikeFunc(s)
 			}
 		} else {
 			// "not column" or "not constant" can't lead to a range.
@@ -145,6 +147,8 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 			c.shouldReserve = true
 		}
 		return c.checkLikeFunc(scalar)
+	case ast.Regexp, ast.RegexpLike:
+		return c.checkRegexpFunc(scalar)
 	case ast.GetParam:
 		return true
 	}
@@ -175,6 +179,35 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 		return true
 	}
 	escape := byte(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
+	switch classifyLikePattern(patternStr, escape) {
+	case likePatternLeadingWildcard:
+		return false
+	case likePatternOtherWildcard:
+		c.shouldReserve = true
+	}
+	return true
+}
+
+// likePatternKind classifies how the unescaped '%'/'_' wildcards of a LIKE pattern are laid out.
+type likePatternKind int
+
+const (
+	// likePatternExact has no wildcard at all, e.g. "abc".
+	likePatternExact likePatternKind = iota
+	// likePatternLeadingWildcard starts with '%' or '_' and can't be used to build a range at all.
+	likePatternLeadingWildcard
+	// likePatternTrailingPercent is a plain prefix pattern, e.g. "abc%", with no other wildcard.
+	likePatternTrailingPercent
+	// likePatternOtherWildcard has a '_' or a non-trailing '%' anywhere else, e.g. "ab_c" or
+	// "ab%c". The positive LIKE range for such a pattern is necessarily a superset of the actual
+	// matches, which is fine for LIKE itself (the reserved filter narrows it back down) but makes
+	// its complement unsound for NOT LIKE.
+	likePatternOtherWildcard
+)
+
+// classifyLikePattern scans a non-empty LIKE pattern, honoring the given escape byte, and reports
+// how its wildcards are laid out.
+func classifyLikePattern(patternStr string, escape byte) likePatternKind {
 	for i := 0; i < len(patternStr); i++ {
 		if patternStr[i] == escape {
 			i++
@@ -184,22 +217,230 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 			break
 		}
 		if i == 0 && (patternStr[i] == '%' || patternStr[i] == '_') {
-			return false
+			return likePatternLeadingWildcard
 		}
 		if patternStr[i] == '%' {
 			if i != len(patternStr)-1 {
-				c.shouldReserve = true
+				return likePatternOtherWildcard
 			}
-			break
+			return likePatternTrailingPercent
 		}
 		if patternStr[i] == '_' {
-			c.shouldReserve = true
+			return likePatternOtherWildcard
+		}
+	}
+	return likePatternExact
+}
+
+// checkNotLikeFunc checks whether a `NOT LIKE` function can be converted to the complementary
+// access range `[-inf, prefix) union [next(prefix), +inf)`. That conversion is only sound when the
+// positive LIKE pattern is an exact match (no wildcard) or a plain prefix match ("prefix%" with no
+// other wildcard): any other wildcard placement makes the positive LIKE range a strict superset of
+// the true matches, so its complement would wrongly exclude rows that legitimately satisfy the
+// NOT LIKE condition. Those patterns, like a leading wildcard, are therefore rejected outright
+// instead of falling back to a reserved filter.
+//
+// NotLikeRangeBounds below is the detacher-side counterpart that turns the accepted prefix into
+// the concrete bound values of that range.
+func (c *conditionChecker) checkNotLikeFunc(scalar *expression.ScalarFunction) bool {
+	_, collation := scalar.CharsetAndCollation(scalar.GetCtx())
+	if !collate.CompatibleCollate(scalar.GetArgs()[0].GetType().Collate, collation) {
+		return false
+	}
+	if !c.checkColumn(scalar.GetArgs()[0]) {
+		return false
+	}
+	pattern, ok := scalar.GetArgs()[1].(*expression.Constant)
+	if !ok {
+		return false
+	}
+	if pattern.Value.IsNull() {
+		return false
+	}
+	patternStr, err := pattern.Value.ToString()
+	if err != nil {
+		return false
+	}
+	if len(patternStr) == 0 {
+		// `NOT LIKE ''` excludes only the empty string, which the prefix range can't express, so
+		// the whole condition must still be double-checked by the filter.
+		c.shouldReserve = true
+		return true
+	}
+	escape := byte(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
+	switch classifyLikePattern(patternStr, escape) {
+	case likePatternLeadingWildcard, likePatternOtherWildcard:
+		return false
+	}
+	return true
+}
+
+// nextPrefix returns a byte string of the same length as the given prefix that is strictly greater
+// than every string which has that prefix. It increments the prefix's last byte, carrying over into
+// the byte before it whenever a byte overflows past 0xff (so []byte{0x01, 0xff} gives
+// []byte{0x02, 0x00}); if every byte overflows, it falls back to the prefix with a trailing 0x00
+// byte appended, which is not greater than the longer strings having that prefix. This is the
+// "next prefix" construction used to turn a prefix match into the upper bound of a range.
+func nextPrefix(prefix []byte) []byte {
+	buf := make([]byte, len(prefix))
+	copy(buf, prefix)
+	i := len(buf) - 1
+	for ; i >= 0; i-- {
+		buf[i]++
+		if buf[i] != 0 {
 			break
 		}
 	}
+	if i < 0 {
+		buf = append(append([]byte{}, prefix...), 0)
+	}
+	return buf
+}
+
+// NotLikeRangeBounds returns the bound of the range excluded by a `NOT LIKE` condition whose
+// positive LIKE pattern was accepted by checkNotLikeFunc, i.e. classifies as likePatternExact or
+// likePatternTrailingPercent. For a plain prefix pattern ("prefix%") the excluded range is the
+// half-open `[prefix, next(prefix))`; for an exact pattern (no wildcard at all) it is the single
+// point `[pattern, pattern]`. In both cases the escape bytes are removed from the bound values.
+// The actual access range is the complement of this bound: `[-inf, excludedLow) union
+// [excludedHigh, +inf)` in the prefix case, and `[-inf, excludedLow) union (excludedHigh, +inf)`
+// in the exact case, where excludedHighInclusive is true.
+func NotLikeRangeBounds(pattern string, escape byte) (excludedLow, excludedHigh []byte, excludedHighInclusive bool) {
+	kind := classifyLikePattern(pattern, escape)
+	if kind == likePatternTrailingPercent {
+		pattern = pattern[:len(pattern)-1]
+	}
+	value := make([]byte, 0, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == escape && i+1 < len(pattern) {
+			i++
+		}
+		value = append(value, pattern[i])
+	}
+	if kind == likePatternTrailingPercent {
+		return value, nextPrefix(value), false
+	}
+	return value, value, true
+}
+
+// checkRegexpFunc checks whether a REGEXP/REGEXP_LIKE function with a constant pattern starts
+// with an unambiguous literal prefix (e.g. `^abc`). If so, it can be treated like `LIKE 'abc%'`
+// for the purpose of building an index access range.
+//
+// RegexpPrefixRangeBounds below is the detacher-side counterpart that turns the extracted prefix
+// into the concrete bound values of that range.
+func (c *conditionChecker) checkRegexpFunc(scalar *expression.ScalarFunction) bool {
+	_, collation := scalar.CharsetAndCollation(scalar.GetCtx())
+	if !collate.CompatibleCollate(scalar.GetArgs()[0].GetType().Collate, collation) {
+		return false
+	}
+	if !c.checkColumn(scalar.GetArgs()[0]) {
+		return false
+	}
+	pattern, ok := scalar.GetArgs()[1].(*expression.Constant)
+	if !ok {
+		return false
+	}
+	if pattern.Value.IsNull() {
+		return false
+	}
+	patternStr, err := pattern.Value.ToString()
+	if err != nil {
+		return false
+	}
+	prefix, exact, ok := extractRegexpLiteralPrefix(patternStr)
+	if !ok || len(prefix) == 0 {
+		return false
+	}
+	// A non-exact prefix (the pattern isn't a plain literal anchored at both ends) only bounds where a match can
+	// start, not what the rest of the value looks like, so the range alone can't decide the
+	// condition and the filter must always be reserved, regardless of isFullLength.
+	if !exact {
+		c.shouldReserve = true
+	}
+	if !c.isFullLength {
+		prefixConst := &expression.Constant{Value: types.NewStringDatum(prefix), RetType: scalar.GetArgs()[1].GetType()}
+		constLen := GetLengthOfPrefixableConstant(prefixConst, scalar.GetArgs()[0].GetType())
+		if constLen == -1 || constLen >= c.length {
+			c.shouldReserve = true
+		}
+	}
 	return true
 }
 
+// RegexpPrefixRangeBounds returns the bound of the access range for a REGEXP/REGEXP_LIKE condition
+// whose literal prefix was extracted by extractRegexpLiteralPrefix and accepted by
+// checkRegexpFunc. When exact is true the pattern is anchored at both ends, so the range collapses
+// to the single point `[prefix, prefix]`; otherwise it is the half-open prefix range
+// `[prefix, next(prefix))`, the same bound `LIKE 'prefix%'` would use.
+func RegexpPrefixRangeBounds(prefix string, exact bool) (low, high []byte, highInclusive bool) {
+	low = []byte(prefix)
+	if exact {
+		return low, low, true
+	}
+	return low, nextPrefix(low), false
+}
+
+// extractRegexpLiteralPrefix scans a regular expression pattern and extracts the unambiguous
+// literal prefix that a match must start with, so it can be used to build an index access range.
+// It requires the pattern to be anchored with a leading `^`, and stops at the first regex
+// metacharacter or escaped letter or digit; a character followed by a `*`, `?` or `{` quantifier
+// is left out as well, since a match may lack it. `exact` reports whether the returned prefix is the whole pattern and the pattern
+// is anchored at both ends (a trailing, unescaped `$`), in which case the prefix is an exact match
+// rather than just a range bound; reaching the end of the pattern without a trailing `$` only
+// means the match may start with the prefix, not that it equals it, so `exact` is false in that
+// case. `ok` is false if no leading `^` is present, or if an unescaped `|` appears anywhere in the
+// pattern: alternation has the lowest precedence in a regular expression, so e.g. `^a|b` means
+// "starts with a" OR "contains b anywhere", which has no literal prefix at all. An unescaped `|`
+// inside a group or a bracket expression also makes `ok` false.
+func extractRegexpLiteralPrefix(pattern string) (prefix string, exact bool, ok bool) {
+	if len(pattern) == 0 || pattern[0] != '^' {
+		return "", false, false
+	}
+	for j := 1; j < len(pattern); j++ {
+		if pattern[j] == '\\' {
+			j++
+		} else if pattern[j] == '|' {
+			return "", false, false
+		}
+	}
+	var buf []byte
+	i := 1
+	for i < len(pattern) {
+		ch := pattern[i]
+		if ch == '$' && i == len(pattern)-1 {
+			// A trailing, unescaped `$` anchors the match at both ends, so the prefix collected
+			// so far is an exact match rather than just a range bound.
+			return string(buf), true, true
+		}
+		switch ch {
+		case '.', '+', '[', '(', '$':
+			return string(buf), false, true
+		case '*', '?', '{':
+			// The quantifier applies to the last character, which a match may then lack.
+			_, size := utf8.DecodeLastRune(buf)
+			return string(buf[:len(buf)-size]), false, true
+		case '\\':
+			if i+1 >= len(pattern) {
+				return string(buf), false, true
+			}
+			// An escaped letter or digit is not that literal character: Perl-style class escapes
+			// (`\d`, `\s`, `\w`, `\b`, and their negations) match a class of characters, and others
+			// such as `\n` or `\x41` stand for another character, so they stop the prefix like any
+			// other metacharacter rather than being decoded as a literal.
+			if next := pattern[i+1]; next >= '0' && next <= '9' || next >= 'a' && next <= 'z' || next >= 'A' && next <= 'Z' {
+				return string(buf), false, true
+			}
+			buf = append(buf, pattern[i+1])
+			i += 2
+			continue
+		}
+		buf = append(buf, ch)
+		i++
+	}
+	return string(buf), false, true
+}
+
 func (c *conditionChecker) checkColumn(expr expression.Expression) bool {
 	col, ok := expr.(*expression.Column)
 	if !ok {
@@ -0,0 +1,273 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ranger
+
+import (
+	"testing"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/charset"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestColumn(id int64, charsetName, collation string, flen int) *expression.Column {
+	ft := types.NewFieldType(mysql.TypeVarString)
+	ft.Charset = charsetName
+	ft.Collate = collation
+	ft.Flen = flen
+	return &expression.Column{UniqueID: id, RetType: ft}
+}
+
+func newNotLikeFunc(ctx *mock.Context, col *expression.Column, pattern string, escape byte) *expression.ScalarFunction {
+	patternConst := &expression.Constant{Value: types.NewStringDatum(pattern), RetType: col.RetType}
+	escapeConst := &expression.Constant{Value: types.NewIntDatum(int64(escape)), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	likeFunc := expression.NewFunctionInternal(ctx, ast.Like, types.NewFieldType(mysql.TypeLonglong), col, patternConst, escapeConst)
+	notFunc := expression.NewFunctionInternal(ctx, ast.UnaryNot, types.NewFieldType(mysql.TypeLonglong), likeFunc)
+	return notFunc.(*expression.ScalarFunction)
+}
+
+func newRegexpFunc(ctx *mock.Context, col *expression.Column, pattern string) *expression.ScalarFunction {
+	patternConst := &expression.Constant{Value: types.NewStringDatum(pattern), RetType: col.RetType}
+	f := expression.NewFunctionInternal(ctx, ast.Regexp, types.NewFieldType(mysql.TypeLonglong), col, patternConst)
+	return f.(*expression.ScalarFunction)
+}
+
+func TestCheckNotLikeFunc(t *testing.T) {
+	ctx := mock.NewContext()
+	tests := []struct {
+		pattern      string
+		isFullLength bool
+		length       int
+		wantOK       bool
+		wantReserve  bool
+	}{
+		// A plain prefix pattern builds a sound complementary range.
+		{pattern: "abc%", isFullLength: true, wantOK: true, wantReserve: false},
+		{pattern: "abc%", isFullLength: false, length: 10, wantOK: true, wantReserve: true},
+		// An exact pattern (no wildcard) is also sound.
+		{pattern: "abc", isFullLength: true, wantOK: true, wantReserve: false},
+		// A leading wildcard can't be expressed as a range at all.
+		{pattern: "%abc", isFullLength: true, wantOK: false},
+		{pattern: "_abc", isFullLength: true, wantOK: false},
+		// A wildcard anywhere other than a single trailing '%' makes the positive LIKE range a
+		// strict superset of the real matches, so the complement must not be built either.
+		{pattern: "ab_c", isFullLength: true, wantOK: false},
+		{pattern: "ab%c", isFullLength: true, wantOK: false},
+		{pattern: "ab_", isFullLength: true, wantOK: false},
+		// The empty pattern excludes only the empty string; it can still be a full-length access
+		// condition, but it must always be reserved as a filter.
+		{pattern: "", isFullLength: true, wantOK: true, wantReserve: true},
+	}
+	for _, tt := range tests {
+		col := newTestColumn(1, charset.CharsetUTF8MB4, "utf8mb4_bin", 255)
+		notFunc := newNotLikeFunc(ctx, col, tt.pattern, '\\')
+		c := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: tt.isFullLength, length: tt.length}
+		ok := c.check(notFunc)
+		require.Equalf(t, tt.wantOK, ok, "pattern %q", tt.pattern)
+		if ok {
+			require.Equalf(t, tt.wantReserve, c.shouldReserve, "pattern %q", tt.pattern)
+		}
+	}
+}
+
+func TestCheckNotLikeFuncCollation(t *testing.T) {
+	ctx := mock.NewContext()
+	col := newTestColumn(1, charset.CharsetUTF8MB4, "utf8mb4_bin", 255)
+	notFunc := newNotLikeFunc(ctx, col, "abc%", '\\')
+	// Mutate the column's collation after the function was built, so it no longer matches the
+	// collation the scalar function derived its arguments from.
+	col.RetType.Collate = "utf8mb4_general_ci"
+	c := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: true}
+	require.False(t, c.check(notFunc))
+}
+
+func TestCheckNotLikeFuncWithIsNull(t *testing.T) {
+	ctx := mock.NewContext()
+	col := newTestColumn(1, charset.CharsetUTF8MB4, "utf8mb4_bin", 255)
+	notFunc := newNotLikeFunc(ctx, col, "abc%", '\\')
+	isNullFunc := expression.NewFunctionInternal(ctx, ast.IsNull, types.NewFieldType(mysql.TypeLonglong), col)
+	orFunc := expression.NewFunctionInternal(ctx, ast.LogicOr, types.NewFieldType(mysql.TypeLonglong), notFunc, isNullFunc)
+	// With a full-length index neither branch needs to reserve a filter: `checkNotLikeFunc` only
+	// reserves for the empty pattern, and IsNull only reserves under a prefix index.
+	cFullLength := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: true}
+	require.True(t, cFullLength.check(orFunc))
+	require.False(t, cFullLength.shouldReserve)
+
+	// Under a prefix index, both branches are gated by `!isFullLength` and always reserve.
+	cPrefix := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: false, length: 10}
+	require.True(t, cPrefix.check(orFunc))
+	require.True(t, cPrefix.shouldReserve)
+}
+
+func TestNextPrefix(t *testing.T) {
+	require.Equal(t, []byte("abd"), nextPrefix([]byte("abc")))
+	require.Equal(t, []byte{0x02, 0x00}, nextPrefix([]byte{0x01, 0xff}))
+	// Every byte overflows: fall back to the prefix with a trailing 0x00 appended.
+	require.Equal(t, []byte{0xff, 0xff, 0x00}, nextPrefix([]byte{0xff, 0xff}))
+}
+
+func TestNotLikeRangeBounds(t *testing.T) {
+	low, high, highIncl := NotLikeRangeBounds("abc%", '\\')
+	require.Equal(t, []byte("abc"), low)
+	require.Equal(t, []byte("abd"), high)
+	require.False(t, highIncl)
+
+	low, high, highIncl = NotLikeRangeBounds("abc", '\\')
+	require.Equal(t, []byte("abc"), low)
+	require.Equal(t, []byte("abc"), high)
+	require.True(t, highIncl)
+}
+
+func TestClassifyLikePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		escape  byte
+		want    likePatternKind
+	}{
+		{pattern: "abc", escape: '\\', want: likePatternExact},
+		{pattern: "abc%", escape: '\\', want: likePatternTrailingPercent},
+		{pattern: "%abc", escape: '\\', want: likePatternLeadingWildcard},
+		{pattern: "_abc", escape: '\\', want: likePatternLeadingWildcard},
+		{pattern: "ab_c", escape: '\\', want: likePatternOtherWildcard},
+		{pattern: "ab%c", escape: '\\', want: likePatternOtherWildcard},
+		// An escaped wildcard is a literal character, not a wildcard.
+		{pattern: `ab\%`, escape: '\\', want: likePatternExact},
+		{pattern: `ab\%c%`, escape: '\\', want: likePatternTrailingPercent},
+	}
+	for _, tt := range tests {
+		require.Equalf(t, tt.want, classifyLikePattern(tt.pattern, tt.escape), "pattern %q", tt.pattern)
+	}
+}
+
+func TestGetLengthOfPrefixableConstant(t *testing.T) {
+	utf8mb4Type := types.NewFieldType(mysql.TypeVarString)
+	utf8mb4Type.Charset = charset.CharsetUTF8MB4
+	binType := types.NewFieldType(mysql.TypeVarString)
+	binType.Charset = charset.CharsetBin
+
+	// utf8mb4 counts runes, not bytes: "中文" is 2 runes but 6 bytes.
+	constUTF8 := &expression.Constant{Value: types.NewStringDatum("中文")}
+	require.Equal(t, 2, GetLengthOfPrefixableConstant(constUTF8, utf8mb4Type))
+
+	// A binary-charset column counts bytes instead.
+	constBin := &expression.Constant{Value: types.NewStringDatum("中文")}
+	require.Equal(t, 6, GetLengthOfPrefixableConstant(constBin, binType))
+
+	// Non-string constants can't be used to bound a range at all.
+	constInt := &expression.Constant{Value: types.NewIntDatum(123)}
+	require.Equal(t, -1, GetLengthOfPrefixableConstant(constInt, utf8mb4Type))
+}
+
+func TestExtractRegexpLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		wantPrefix string
+		wantExact  bool
+		wantOK     bool
+	}{
+		{pattern: "^abc", wantPrefix: "abc", wantExact: false, wantOK: true},
+		{pattern: "^abc$", wantPrefix: "abc", wantExact: true, wantOK: true},
+		// No leading '^' means the match can start anywhere, so no prefix can be derived.
+		{pattern: "abc", wantOK: false},
+		// Alternation has the lowest precedence, so "^a|b" means "starts with a" OR "contains b
+		// anywhere", not "starts with a"; it must not be reduced to a prefix at all.
+		{pattern: "^a|b", wantOK: false},
+		// A metacharacter right after '^' leaves no usable literal prefix.
+		{pattern: "^.*x", wantPrefix: "", wantExact: false, wantOK: true},
+		// Escaped metacharacters are literal.
+		{pattern: `^a\.b`, wantPrefix: "a.b", wantExact: false, wantOK: true},
+		{pattern: `^a\\b`, wantPrefix: `a\b`, wantExact: false, wantOK: true},
+		// The prefix stops at the first unescaped metacharacter.
+		{pattern: "^ab[cd]", wantPrefix: "ab", wantExact: false, wantOK: true},
+		// Perl-style class escapes match a class of characters, not the escaped letter, so they
+		// must stop the prefix too instead of being decoded as literal text.
+		{pattern: `^\d{3}-\d{4}`, wantPrefix: "", wantExact: false, wantOK: true},
+		{pattern: `^ab\w`, wantPrefix: "ab", wantExact: false, wantOK: true},
+		{pattern: `^ab\S`, wantPrefix: "ab", wantExact: false, wantOK: true},
+	}
+	for _, tt := range tests {
+		prefix, exact, ok := extractRegexpLiteralPrefix(tt.pattern)
+		require.Equalf(t, tt.wantOK, ok, "pattern %q", tt.pattern)
+		if ok {
+			require.Equalf(t, tt.wantPrefix, prefix, "pattern %q", tt.pattern)
+			require.Equalf(t, tt.wantExact, exact, "pattern %q", tt.pattern)
+		}
+	}
+}
+
+func TestCheckRegexpFunc(t *testing.T) {
+	ctx := mock.NewContext()
+	tests := []struct {
+		pattern      string
+		isFullLength bool
+		length       int
+		wantOK       bool
+		wantReserve  bool
+	}{
+		// An unanchored prefix match still needs the filter to confirm the rest of the pattern,
+		// even with a full-length index (no prefix truncation involved).
+		{pattern: "^abc", isFullLength: true, wantOK: true, wantReserve: true},
+		{pattern: "^abc", isFullLength: false, length: 10, wantOK: true, wantReserve: true},
+		// `^abc.*xyz` and `^ab[0-9]+` extract a non-exact prefix too: the range only bounds where
+		// a match can start, it says nothing about the required suffix, so the filter must stay.
+		{pattern: "^abc.*xyz", isFullLength: true, wantOK: true, wantReserve: true},
+		{pattern: "^ab[0-9]+", isFullLength: true, wantOK: true, wantReserve: true},
+		// A pattern anchored at both ends is an exact match, like an equality comparison, so a
+		// full-length index doesn't need the filter kept.
+		{pattern: "^abc$", isFullLength: true, wantOK: true, wantReserve: false},
+		// A pattern with no leading '^' stays a full filter.
+		{pattern: "abc", isFullLength: true, wantOK: false},
+		// Top-level alternation stays a full filter; it must not be reduced to range [a, b).
+		{pattern: "^a|b", isFullLength: true, wantOK: false},
+		// A pattern with no usable literal prefix stays a full filter.
+		{pattern: "^.*x", isFullLength: true, wantOK: false},
+	}
+	for _, tt := range tests {
+		col := newTestColumn(1, charset.CharsetUTF8MB4, "utf8mb4_bin", 255)
+		regexpFunc := newRegexpFunc(ctx, col, tt.pattern)
+		c := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: tt.isFullLength, length: tt.length}
+		ok := c.check(regexpFunc)
+		require.Equalf(t, tt.wantOK, ok, "pattern %q", tt.pattern)
+		if ok {
+			require.Equalf(t, tt.wantReserve, c.shouldReserve, "pattern %q", tt.pattern)
+		}
+	}
+}
+
+func TestCheckRegexpFuncCollation(t *testing.T) {
+	ctx := mock.NewContext()
+	col := newTestColumn(1, charset.CharsetUTF8MB4, "utf8mb4_bin", 255)
+	regexpFunc := newRegexpFunc(ctx, col, "^abc")
+	// Mutate the column's collation after the function was built, so it no longer matches the
+	// collation the scalar function derived its arguments from.
+	col.RetType.Collate = "utf8mb4_general_ci"
+	c := &conditionChecker{colUniqueID: col.UniqueID, isFullLength: true}
+	require.False(t, c.check(regexpFunc))
+}
+
+func TestRegexpPrefixRangeBounds(t *testing.T) {
+	low, high, highIncl := RegexpPrefixRangeBounds("abc", false)
+	require.Equal(t, []byte("abc"), low)
+	require.Equal(t, []byte("abd"), high)
+	require.False(t, highIncl)
+
+	low, high, highIncl = RegexpPrefixRangeBounds("abc", true)
+	require.Equal(t, []byte("abc"), low)
+	require.Equal(t, []byte("abc"), high)
+	require.True(t, highIncl)
+}